@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretUsernameKey and secretPasswordKey are the well-known Secret data
+// keys that store a vCenter's username and password.
+const (
+	secretUsernameKey = "username"
+	secretPasswordKey = "password"
+)
+
+// ResolveOptions defines the options used to influence how Resolve behaves.
+type ResolveOptions struct {
+	// RequireResolvedCredentials causes Resolve to return an error if,
+	// after resolution, any vCenter still lacks a username or a
+	// password.
+	RequireResolvedCredentials bool
+}
+
+// ResolveOptionFunc is used to set resolve options.
+type ResolveOptionFunc func(*ResolveOptions)
+
+// RequireResolvedCredentials sets the option to fail Resolve when a
+// vCenter's credentials cannot be resolved.
+func RequireResolvedCredentials(opts *ResolveOptions) {
+	opts.RequireResolvedCredentials = true
+}
+
+// CredentialResolver resolves the Username/Password of each of a
+// Config's VCenterConfig entries, e.g. by looking up a referenced Secret.
+type CredentialResolver interface {
+	Resolve(ctx context.Context, c *Config, optFuncs ...ResolveOptionFunc) error
+}
+
+// SecretCredentialResolver is a CredentialResolver that resolves
+// credentials from Kubernetes Secrets referenced by a VCenterConfig's own
+// SecretName/SecretNamespace, falling back to GlobalConfig's.
+type SecretCredentialResolver struct {
+	Client client.Client
+}
+
+var _ CredentialResolver = &SecretCredentialResolver{}
+
+// Resolve populates the Username/Password of every VCenterConfig in c
+// that does not already carry inline credentials, by looking up the
+// Secret referenced by the vCenter (falling back to the Global secret
+// reference) and reading its "username"/"password" data keys.
+func (r *SecretCredentialResolver) Resolve(ctx context.Context, c *Config, optFuncs ...ResolveOptionFunc) error {
+	if c == nil {
+		return errors.New("config is nil")
+	}
+
+	opts := &ResolveOptions{}
+	for _, setOpts := range optFuncs {
+		setOpts(opts)
+	}
+
+	for vc, cfg := range c.VCenter {
+		if cfg.Username != "" && cfg.Password != "" {
+			continue
+		}
+
+		secretName, secretNamespace := cfg.SecretName, cfg.SecretNamespace
+		if secretName == "" {
+			secretName, secretNamespace = c.Global.SecretName, c.Global.SecretNamespace
+		}
+		if secretName != "" {
+			secret := &corev1.Secret{}
+			key := apitypes.NamespacedName{Name: secretName, Namespace: secretNamespace}
+			if err := r.Client.Get(ctx, key, secret); err != nil {
+				return errors.Wrapf(err, "unable to get secret %s for vCenter %s", key, vc)
+			}
+			if cfg.Username == "" {
+				cfg.Username = string(secret.Data[secretUsernameKey])
+			}
+			if cfg.Password == "" {
+				cfg.Password = string(secret.Data[secretPasswordKey])
+			}
+			c.VCenter[vc] = cfg
+		}
+
+		if opts.RequireResolvedCredentials && (cfg.Username == "" || cfg.Password == "") {
+			return errors.Errorf("unable to resolve credentials for vCenter %s", vc)
+		}
+	}
+
+	return nil
+}
+
+// Validate reports whether every vCenter in c has a source of
+// credentials: either inline Username/Password (its own, falling back to
+// Global's), or a SecretName (its own, or Global's) that a
+// CredentialResolver could in principle resolve.
+func (c *Config) Validate() error {
+	if c == nil {
+		return errors.New("config is nil")
+	}
+
+	var missing []string
+	for vc, cfg := range c.VCenter {
+		username, password := cfg.Username, cfg.Password
+		if username == "" {
+			username = c.Global.Username
+		}
+		if password == "" {
+			password = c.Global.Password
+		}
+		if username != "" && password != "" {
+			continue
+		}
+		if cfg.SecretName != "" || c.Global.SecretName != "" {
+			continue
+		}
+		missing = append(missing, vc)
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf("vCenter(s) without resolvable credentials: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// Sanitized returns a copy of c with every Username/Password cleared, so
+// the result is safe to marshal and persist without leaking credentials.
+func (c *Config) Sanitized() *Config {
+	if c == nil {
+		return nil
+	}
+
+	sanitized := *c
+	sanitized.Global.Username = ""
+	sanitized.Global.Password = ""
+
+	sanitized.VCenter = make(map[string]VCenterConfig, len(c.VCenter))
+	for vc, cfg := range c.VCenter {
+		cfg.Username = ""
+		cfg.Password = ""
+		sanitized.VCenter[vc] = cfg
+	}
+
+	return &sanitized
+}
+
+// SanitizedINI marshals c to INI-style configuration data with every
+// Username/Password cleared, so the result is safe to persist once
+// credentials have been resolved elsewhere (e.g. into a Secret).
+func (c *Config) SanitizedINI() ([]byte, error) {
+	return c.Sanitized().MarshalINI()
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/api/v1alpha2/cloud"
+)
+
+func newVCCredsSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vccreds",
+			Namespace: "kube-system",
+		},
+		Data: map[string][]byte{
+			"username": []byte("secret-user"),
+			"password": []byte("secret-password"),
+		},
+	}
+}
+
+func TestSecretCredentialResolverResolve(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	fakeClient := fake.NewFakeClient(newVCCredsSecret())
+	resolver := &cloud.SecretCredentialResolver{Client: fakeClient}
+
+	cfg := &cloud.Config{
+		Global: cloud.GlobalConfig{
+			SecretName:      "vccreds",
+			SecretNamespace: "kube-system",
+		},
+		VCenter: map[string]cloud.VCenterConfig{
+			"0.0.0.0": {},
+			"1.1.1.1": {Username: "already-set", Password: "already-set"},
+		},
+	}
+
+	g.Expect(resolver.Resolve(context.Background(), cfg)).To(gomega.Succeed())
+	g.Expect(cfg.VCenter["0.0.0.0"].Username).Should(gomega.Equal("secret-user"))
+	g.Expect(cfg.VCenter["0.0.0.0"].Password).Should(gomega.Equal("secret-password"))
+	g.Expect(cfg.VCenter["1.1.1.1"].Username).Should(gomega.Equal("already-set"))
+}
+
+func TestSecretCredentialResolverRequireResolvedCredentials(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	fakeClient := fake.NewFakeClient()
+	resolver := &cloud.SecretCredentialResolver{Client: fakeClient}
+
+	cfg := &cloud.Config{
+		VCenter: map[string]cloud.VCenterConfig{
+			"0.0.0.0": {},
+		},
+	}
+
+	err := resolver.Resolve(context.Background(), cfg, cloud.RequireResolvedCredentials)
+	g.Expect(err).Should(gomega.HaveOccurred())
+}
+
+func TestConfigValidate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	valid := &cloud.Config{
+		Global: cloud.GlobalConfig{SecretName: "vccreds"},
+		VCenter: map[string]cloud.VCenterConfig{
+			"0.0.0.0": {},
+			"1.1.1.1": {Username: "user", Password: "password"},
+		},
+	}
+	g.Expect(valid.Validate()).To(gomega.Succeed())
+
+	invalid := &cloud.Config{
+		VCenter: map[string]cloud.VCenterConfig{
+			"0.0.0.0": {},
+		},
+	}
+	g.Expect(invalid.Validate()).Should(gomega.HaveOccurred())
+}
+
+func TestConfigSanitizedINI(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cfg := &cloud.Config{
+		Global: cloud.GlobalConfig{
+			Username: "user",
+			Password: "password",
+		},
+		VCenter: map[string]cloud.VCenterConfig{
+			"0.0.0.0": {Username: "vc-user", Password: "vc-password"},
+		},
+		Workspace: cloud.WorkspaceConfig{
+			Server:     "0.0.0.0",
+			Datacenter: "us-west",
+			Folder:     "kubernetes",
+		},
+	}
+
+	buf, err := cfg.SanitizedINI()
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+	g.Expect(string(buf)).ShouldNot(gomega.ContainSubstring("vc-password"))
+	g.Expect(string(buf)).ShouldNot(gomega.ContainSubstring("password = password"))
+
+	// The original Config is left untouched.
+	g.Expect(cfg.Global.Password).Should(gomega.Equal("password"))
+}
@@ -147,6 +147,21 @@ type UnmarshalINIOptions struct {
 	// WarnAsFatal indicates that warnings that occur when unmarshalling INI
 	// data should be treated as fatal errors.
 	WarnAsFatal bool
+
+	// ExpandEnv indicates that, after parsing, every string field should
+	// be expanded for ${VAR}/$VAR references using Expander.
+	ExpandEnv bool
+
+	// StrictExpandEnv indicates that expansion should fail if a
+	// referenced variable cannot be resolved, rather than leaving the
+	// reference untouched. Takes effect only when ExpandEnv is also set;
+	// the StrictExpandEnv option func sets both.
+	StrictExpandEnv bool
+
+	// Expander resolves the variable references expanded when ExpandEnv
+	// is set. Defaults to EnvValueExpander, which resolves references
+	// using os.LookupEnv.
+	Expander ValueExpander
 }
 
 // UnmarshalINIOptionFunc is used to set unmarshal options.
@@ -160,6 +175,28 @@ func WarnAsFatal(opts *UnmarshalINIOptions) {
 	opts.WarnAsFatal = true
 }
 
+// ExpandEnv sets the option to expand ${VAR}/$VAR references in every
+// string field using the configured ValueExpander (os.LookupEnv by
+// default), leaving unresolved references untouched.
+func ExpandEnv(opts *UnmarshalINIOptions) {
+	opts.ExpandEnv = true
+}
+
+// StrictExpandEnv sets the option to expand ${VAR}/$VAR references in
+// every string field, failing if a reference cannot be resolved.
+func StrictExpandEnv(opts *UnmarshalINIOptions) {
+	opts.ExpandEnv = true
+	opts.StrictExpandEnv = true
+}
+
+// WithValueExpander sets the ValueExpander used to resolve variable
+// references when ExpandEnv or StrictExpandEnv is set.
+func WithValueExpander(expander ValueExpander) UnmarshalINIOptionFunc {
+	return func(opts *UnmarshalINIOptions) {
+		opts.Expander = expander
+	}
+}
+
 // UnmarshalINI unmarshals the cloud provider configuration from INI-style
 // configuration data.
 func (c *Config) UnmarshalINI(data []byte, optFuncs ...UnmarshalINIOptionFunc) error {
@@ -185,6 +222,13 @@ func (c *Config) UnmarshalINI(data []byte, optFuncs ...UnmarshalINIOptionFunc) e
 	for k, v := range config.VCenter {
 		c.VCenter[k] = *v
 	}
+
+	if opts.ExpandEnv {
+		if err := expandConfig(c, opts); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
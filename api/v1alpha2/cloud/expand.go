@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ValueExpander resolves the variable references found inside a Config's
+// string fields when ExpandEnv/StrictExpandEnv is set. Alternate
+// implementations can source values from somewhere other than the
+// process environment, e.g. a file:///path reference or a Kubernetes
+// downward-API file.
+type ValueExpander interface {
+	// Expand looks up name -- the bare identifier that appeared inside a
+	// ${name} or $name reference -- and returns its value and whether it
+	// could be resolved.
+	Expand(name string) (string, bool)
+}
+
+// EnvValueExpander is the default ValueExpander. It resolves references
+// using os.LookupEnv.
+type EnvValueExpander struct{}
+
+// Expand implements ValueExpander.
+func (EnvValueExpander) Expand(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+// applyExpandEnvOpts expands c's string fields if any of optFuncs set
+// ExpandEnv. It exists so callers that unmarshal via methods which can't
+// themselves take variadic options (e.g. the stdlib json.Unmarshaler
+// interface) can still opt into expansion.
+func applyExpandEnvOpts(c *Config, optFuncs []UnmarshalINIOptionFunc) error {
+	opts := &UnmarshalINIOptions{}
+	for _, setOpts := range optFuncs {
+		setOpts(opts)
+	}
+	if !opts.ExpandEnv {
+		return nil
+	}
+	return expandConfig(c, opts)
+}
+
+// expandConfig walks every string field of c, replacing ${VAR}/$VAR
+// references using opts.Expander (defaulting to EnvValueExpander). In
+// strict mode, an unresolved reference is a fatal error; otherwise it is
+// left untouched.
+func expandConfig(c *Config, opts *UnmarshalINIOptions) error {
+	expander := opts.Expander
+	if expander == nil {
+		expander = EnvValueExpander{}
+	}
+	return expandValue(reflect.ValueOf(c).Elem(), expander, opts.StrictExpandEnv)
+}
+
+func expandValue(val reflect.Value, expander ValueExpander, strict bool) error {
+	switch val.Kind() {
+	case reflect.String:
+		if !val.CanSet() {
+			return nil
+		}
+		expanded, err := expandString(val.String(), expander, strict)
+		if err != nil {
+			return err
+		}
+		val.SetString(expanded)
+		return nil
+
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			if err := expandValue(val.Field(i), expander, strict); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			k, v := iter.Key(), iter.Value()
+			// Map values aren't addressable; expand a copy and write it
+			// back.
+			elem := reflect.New(v.Type()).Elem()
+			elem.Set(v)
+			if err := expandValue(elem, expander, strict); err != nil {
+				return err
+			}
+			val.SetMapIndex(k, elem)
+		}
+		return nil
+
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		return expandValue(val.Elem(), expander, strict)
+
+	default:
+		return nil
+	}
+}
+
+// expandString replaces ${VAR} and $VAR references in s using expander.
+// "$$" is treated as an escaped, literal "$" and is never expanded.
+func expandString(s string, expander ValueExpander, strict bool) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i == len(s)-1 {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		// Escaped literal: "$$" collapses to a single, unexpanded "$".
+		if s[i+1] == '$' {
+			out.WriteByte('$')
+			i++
+			continue
+		}
+
+		name, width, braced := parseVarRef(s[i+1:])
+		if name == "" {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		value, ok := expander.Expand(name)
+		if !ok {
+			if strict {
+				return "", errors.Errorf("unable to resolve variable reference %q", name)
+			}
+			out.WriteByte(s[i])
+			if braced {
+				out.WriteString("{" + name + "}")
+			} else {
+				out.WriteString(name)
+			}
+			i += width
+			continue
+		}
+
+		out.WriteString(value)
+		i += width
+	}
+	return out.String(), nil
+}
+
+// parseVarRef parses a "${name}" or "name" identifier from the start of s
+// (s is everything following the "$" that introduced the reference) and
+// returns the identifier, how many bytes of s it consumed, and whether it
+// was brace-delimited.
+func parseVarRef(s string) (name string, width int, braced bool) {
+	if len(s) == 0 {
+		return "", 0, false
+	}
+
+	if s[0] == '{' {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return "", 0, false
+		}
+		return s[1:end], end + 1, true
+	}
+
+	end := 0
+	for end < len(s) && isVarNameByte(s[end], end == 0) {
+		end++
+	}
+	return s[:end], end, false
+}
+
+func isVarNameByte(b byte, first bool) bool {
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b == '_':
+		return true
+	case b >= '0' && b <= '9':
+		return !first
+	default:
+		return false
+	}
+}
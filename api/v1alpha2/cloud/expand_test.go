@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/api/v1alpha2/cloud"
+)
+
+func TestUnmarshalINIExpandEnv(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(os.Setenv("CAPV_TEST_PASSWORD", "s3cr3t")).To(gomega.Succeed())
+	defer os.Unsetenv("CAPV_TEST_PASSWORD") // nolint:errcheck
+
+	iniString := `
+[Global]
+user = user
+password = ${CAPV_TEST_PASSWORD}
+datacenters = us-west
+
+[VirtualCenter "0.0.0.0"]
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+
+	var actual cloud.Config
+	g.Expect(actual.UnmarshalINI([]byte(iniString), cloud.ExpandEnv)).To(gomega.Succeed())
+	g.Expect(actual.Global.Password).Should(gomega.Equal("s3cr3t"))
+}
+
+func TestUnmarshalINIStrictExpandEnvMissingVar(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	iniString := `
+[Global]
+password = $CAPV_TEST_DOES_NOT_EXIST
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+
+	var actual cloud.Config
+	err := actual.UnmarshalINI([]byte(iniString), cloud.StrictExpandEnv)
+	g.Expect(err).Should(gomega.HaveOccurred())
+}
+
+func TestUnmarshalINIExpandEnvEscapedLiteral(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	iniString := `
+[Global]
+password = $$NOT_EXPANDED
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+
+	var actual cloud.Config
+	g.Expect(actual.UnmarshalINI([]byte(iniString), cloud.ExpandEnv)).To(gomega.Succeed())
+	g.Expect(actual.Global.Password).Should(gomega.Equal("$NOT_EXPANDED"))
+}
+
+func TestUnmarshalINIExpandEnvMissingVarNonStrict(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	iniString := `
+[Global]
+password = $CAPV_TEST_DOES_NOT_EXIST
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+
+	var actual cloud.Config
+	g.Expect(actual.UnmarshalINI([]byte(iniString), cloud.ExpandEnv)).To(gomega.Succeed())
+	g.Expect(actual.Global.Password).Should(gomega.Equal("$CAPV_TEST_DOES_NOT_EXIST"))
+}
+
+func TestUnmarshalYAMLExpandEnv(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(os.Setenv("CAPV_TEST_PASSWORD", "s3cr3t")).To(gomega.Succeed())
+	defer os.Unsetenv("CAPV_TEST_PASSWORD") // nolint:errcheck
+
+	yamlString := `
+global:
+  user: user
+  password: ${CAPV_TEST_PASSWORD}
+  datacenters: us-west
+virtualCenter:
+  0.0.0.0: {}
+workspace:
+  server: 0.0.0.0
+  datacenter: us-west
+  folder: kubernetes
+`
+
+	var actual cloud.Config
+	g.Expect(actual.UnmarshalYAML([]byte(yamlString), cloud.ExpandEnv)).To(gomega.Succeed())
+	g.Expect(actual.Global.Password).Should(gomega.Equal("s3cr3t"))
+}
+
+func TestUnmarshalYAMLStrictExpandEnvMissingVar(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	yamlString := `
+global:
+  password: $CAPV_TEST_DOES_NOT_EXIST
+workspace:
+  server: 0.0.0.0
+  datacenter: us-west
+  folder: kubernetes
+`
+
+	var actual cloud.Config
+	err := actual.UnmarshalYAML([]byte(yamlString), cloud.StrictExpandEnv)
+	g.Expect(err).Should(gomega.HaveOccurred())
+}
+
+func TestUnmarshalYAMLExpandEnvEscapedLiteral(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	yamlString := `
+global:
+  password: $$NOT_EXPANDED
+workspace:
+  server: 0.0.0.0
+  datacenter: us-west
+  folder: kubernetes
+`
+
+	var actual cloud.Config
+	g.Expect(actual.UnmarshalYAML([]byte(yamlString), cloud.ExpandEnv)).To(gomega.Succeed())
+	g.Expect(actual.Global.Password).Should(gomega.Equal("$NOT_EXPANDED"))
+}
+
+type staticValueExpander map[string]string
+
+func (s staticValueExpander) Expand(name string) (string, bool) {
+	v, ok := s[name]
+	return v, ok
+}
+
+func TestUnmarshalINIExpandEnvCustomExpander(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	iniString := `
+[Global]
+ca-file = ${ca_path}
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+
+	expander := staticValueExpander{"ca_path": "file:///etc/certs/ca.pem"}
+
+	var actual cloud.Config
+	g.Expect(actual.UnmarshalINI(
+		[]byte(iniString),
+		cloud.ExpandEnv,
+		cloud.WithValueExpander(expander),
+	)).To(gomega.Succeed())
+	g.Expect(actual.Global.CAFile).Should(gomega.Equal("file:///etc/certs/ca.pem"))
+}
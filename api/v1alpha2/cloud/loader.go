@@ -0,0 +1,281 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	gcfg "gopkg.in/gcfg.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// Source identifies a single configuration input. It is analogous to a
+// single path in a KUBECONFIG colon-separated list: either Path or Reader
+// should be set, and Path is preferred when both are.
+type Source struct {
+	// Path is the path to a config file on disk. The file may be
+	// INI, YAML, or JSON; the format is auto-detected via LoadConfig.
+	Path string
+
+	// Reader, when Path is empty, supplies the configuration data
+	// directly.
+	Reader io.Reader
+}
+
+// ConfigOverrides force specific values onto a Config after it has been
+// loaded and merged, regardless of what the underlying Sources contained.
+// It mirrors the role clientcmd.ConfigOverrides plays for kubeconfig: a
+// small, explicit set of fields a caller (e.g. a command line flag) may
+// need to force irrespective of precedence.
+type ConfigOverrides struct {
+	// Insecure, when non-nil, forces Global.Insecure to this value.
+	Insecure *bool
+
+	// CAFile, when non-empty, forces Global.CAFile to this value.
+	CAFile string
+
+	// VCenterCredentials, when set, forces the Username/Password of the
+	// named vCenter's VCenterConfig, creating the entry if it does not
+	// already exist.
+	VCenterCredentials map[string]VCenterConfig
+}
+
+// apply forces the override values onto c.
+func (o *ConfigOverrides) apply(c *Config) {
+	if o == nil {
+		return
+	}
+	if o.Insecure != nil {
+		c.Global.Insecure = *o.Insecure
+	}
+	if o.CAFile != "" {
+		c.Global.CAFile = o.CAFile
+	}
+	for vc, creds := range o.VCenterCredentials {
+		existing := c.VCenter[vc]
+		if creds.Username != "" {
+			existing.Username = creds.Username
+		}
+		if creds.Password != "" {
+			existing.Password = creds.Password
+		}
+		if c.VCenter == nil {
+			c.VCenter = map[string]VCenterConfig{}
+		}
+		c.VCenter[vc] = existing
+	}
+}
+
+// Loader loads and merges a Config from an ordered list of Sources,
+// mirroring the merge semantics of k8s.io/client-go/tools/clientcmd:
+// earlier Sources win for scalar fields, while later Sources contribute
+// any VirtualCenter entries not already present.
+type Loader struct {
+	// Sources are read in order. Earlier Sources take precedence for
+	// scalar fields; later Sources only add VirtualCenter entries that
+	// have not already been supplied by an earlier Source.
+	Sources []Source
+
+	// EnvVar, if set, is the name of an environment variable containing
+	// an additional, colon-separated list of file paths to append to
+	// Sources, analogous to KUBECONFIG.
+	EnvVar string
+
+	// UnmarshalOptions are passed through to LoadConfig for every Source.
+	// The expansion options apply to INI, YAML, and JSON Sources alike;
+	// WarnAsFatal only affects INI-formatted Sources.
+	UnmarshalOptions []UnmarshalINIOptionFunc
+
+	// Overrides, if set, are applied to the merged Config before Load
+	// returns.
+	Overrides *ConfigOverrides
+}
+
+// Load reads and merges all of the Loader's Sources into a single Config.
+func (l *Loader) Load() (*Config, error) {
+	merged := &Config{}
+	insecureSet := false
+
+	for i, data := range l.sourcePaths() {
+		data := data
+		b, err := readSource(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read source %d", i)
+		}
+		cfg, err := LoadConfig(b, l.UnmarshalOptions...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to load source %d", i)
+		}
+		srcInsecureSet, err := globalInsecureSet(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to determine field presence for source %d", i)
+		}
+		mergeConfig(merged, cfg, srcInsecureSet, &insecureSet)
+	}
+
+	l.Overrides.apply(merged)
+
+	return merged, nil
+}
+
+// sourcePaths returns l.Sources plus any paths contributed by l.EnvVar, in
+// the order they should be merged.
+func (l *Loader) sourcePaths() []Source {
+	sources := l.Sources
+	if l.EnvVar == "" {
+		return sources
+	}
+	val := os.Getenv(l.EnvVar)
+	if val == "" {
+		return sources
+	}
+	for _, p := range strings.Split(val, ":") {
+		if p == "" {
+			continue
+		}
+		sources = append(sources, Source{Path: p})
+	}
+	return sources
+}
+
+func readSource(s Source) ([]byte, error) {
+	if s.Path != "" {
+		return ioutil.ReadFile(s.Path)
+	}
+	if s.Reader != nil {
+		return ioutil.ReadAll(s.Reader)
+	}
+	return nil, errors.New("source has neither a path nor a reader")
+}
+
+// mergeConfig merges src into dst in place: dst's existing scalar field
+// values win, and any VirtualCenter entries in src that dst does not
+// already have are added to dst. srcInsecureSet reports whether src's
+// source data explicitly set Global.Insecure, and insecureSet tracks
+// whether some earlier, higher-precedence source already did so; both
+// are required because the bool zero value can't distinguish "false"
+// from "unset".
+func mergeConfig(dst, src *Config, srcInsecureSet bool, insecureSet *bool) {
+	mergeGlobalConfig(&dst.Global, src.Global, srcInsecureSet, insecureSet)
+
+	if dst.Workspace == (WorkspaceConfig{}) {
+		dst.Workspace = src.Workspace
+	}
+	if dst.Network == (NetworkConfig{}) {
+		dst.Network = src.Network
+	}
+	if dst.Disk == (DiskConfig{}) {
+		dst.Disk = src.Disk
+	}
+	if dst.Labels == (LabelConfig{}) {
+		dst.Labels = src.Labels
+	}
+
+	if dst.VCenter == nil {
+		dst.VCenter = map[string]VCenterConfig{}
+	}
+	for vc, cfg := range src.VCenter {
+		if _, ok := dst.VCenter[vc]; !ok {
+			dst.VCenter[vc] = cfg
+		}
+	}
+}
+
+// mergeGlobalConfig fills in any empty fields of dst with the
+// corresponding field of src, leaving dst's non-empty fields untouched.
+// Insecure is handled separately from the other fields: its zero value
+// (false) is indistinguishable from "not set", so srcInsecureSet and
+// insecureSet are used to apply the first explicit value seen instead.
+func mergeGlobalConfig(dst *GlobalConfig, src GlobalConfig, srcInsecureSet bool, insecureSet *bool) {
+	if dst.Username == "" {
+		dst.Username = src.Username
+	}
+	if dst.Password == "" {
+		dst.Password = src.Password
+	}
+	if dst.Port == "" {
+		dst.Port = src.Port
+	}
+	if !*insecureSet && srcInsecureSet {
+		dst.Insecure = src.Insecure
+		*insecureSet = true
+	}
+	if dst.Datacenters == "" {
+		dst.Datacenters = src.Datacenters
+	}
+	if dst.SecretName == "" {
+		dst.SecretName = src.SecretName
+	}
+	if dst.SecretNamespace == "" {
+		dst.SecretNamespace = src.SecretNamespace
+	}
+	if dst.CAFile == "" {
+		dst.CAFile = src.CAFile
+	}
+}
+
+// globalPresence mirrors the fields of GlobalConfig whose zero value is
+// ambiguous with "not set" using pointers, so a source's raw data can be
+// probed for explicit presence independently of LoadConfig's parsed
+// result.
+//
+// +kubebuilder:object:generate=false
+type globalPresence struct {
+	Insecure *bool `gcfg:"insecure-flag" json:"insecure" yaml:"insecure"`
+}
+
+// configPresence is the Config-shaped wrapper globalPresence is probed
+// through, mirroring unmarshallableConfig's role for UnmarshalINI.
+//
+// +kubebuilder:object:generate=false
+type configPresence struct {
+	Global globalPresence `gcfg:"Global" json:"global" yaml:"global"`
+}
+
+// globalInsecureSet reports whether data explicitly sets Global.Insecure,
+// regardless of whether data is INI, YAML, or JSON.
+func globalInsecureSet(data []byte) (bool, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return false, nil
+	}
+
+	var p configPresence
+	switch {
+	case trimmed[0] == '{':
+		if err := json.Unmarshal(trimmed, &p); err != nil {
+			return false, errors.Wrap(err, "unable to unmarshal JSON config")
+		}
+	case isINI(trimmed):
+		if err := gcfg.ReadStringInto(&p, string(trimmed)); err != nil {
+			if err := gcfg.FatalOnly(err); err != nil {
+				return false, errors.Wrap(err, "unable to unmarshal INI config")
+			}
+		}
+	default:
+		if err := yaml.Unmarshal(trimmed, &p); err != nil {
+			return false, errors.Wrap(err, "unable to unmarshal YAML config")
+		}
+	}
+	return p.Global.Insecure != nil, nil
+}
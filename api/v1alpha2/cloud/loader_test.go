@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/api/v1alpha2/cloud"
+)
+
+func TestLoaderEmptySources(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	l := &cloud.Loader{}
+	cfg, err := l.Load()
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+	g.Expect(*cfg).Should(gomega.Equal(cloud.Config{}))
+}
+
+func TestLoaderConflictingGlobalFields(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	first := `
+[Global]
+user = first-user
+datacenters = us-west
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+	second := `
+[Global]
+user = second-user
+password = second-password
+datacenters = us-east
+
+[Workspace]
+server = 1.1.1.1
+datacenter = us-east
+folder = kubernetes
+`
+
+	l := &cloud.Loader{
+		Sources: []cloud.Source{
+			{Reader: strings.NewReader(first)},
+			{Reader: strings.NewReader(second)},
+		},
+	}
+	cfg, err := l.Load()
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	// The first source wins for fields it sets...
+	g.Expect(cfg.Global.Username).Should(gomega.Equal("first-user"))
+	g.Expect(cfg.Global.Datacenters).Should(gomega.Equal("us-west"))
+	// ...but the second source fills in fields the first source left empty.
+	g.Expect(cfg.Global.Password).Should(gomega.Equal("second-password"))
+}
+
+func TestLoaderConflictingInsecureFlag(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	first := `
+[Global]
+user = user
+insecure-flag = false
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+	second := `
+[Global]
+user = user
+insecure-flag = true
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+
+	l := &cloud.Loader{
+		Sources: []cloud.Source{
+			{Reader: strings.NewReader(first)},
+			{Reader: strings.NewReader(second)},
+		},
+	}
+	cfg, err := l.Load()
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	// The first source explicitly sets insecure-flag = false, so the
+	// second source's insecure-flag = true must not override it.
+	g.Expect(cfg.Global.Insecure).Should(gomega.BeFalse())
+}
+
+func TestLoaderPerVCenterMerging(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	first := `
+[Global]
+user = user
+password = password
+datacenters = us-west
+
+[VirtualCenter "0.0.0.0"]
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+	second := `
+[Global]
+user = user
+password = password
+datacenters = us-west
+
+[VirtualCenter "0.0.0.0"]
+user = should-not-win
+
+[VirtualCenter "1.1.1.1"]
+thumbprint = thumbprint:1
+
+[Workspace]
+server = 0.0.0.0
+datacenter = us-west
+folder = kubernetes
+`
+
+	l := &cloud.Loader{
+		Sources: []cloud.Source{
+			{Reader: strings.NewReader(first)},
+			{Reader: strings.NewReader(second)},
+		},
+	}
+	cfg, err := l.Load()
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	g.Expect(cfg.VCenter).Should(gomega.HaveLen(2))
+	g.Expect(cfg.VCenter["0.0.0.0"].Username).Should(gomega.Equal(""))
+	g.Expect(cfg.VCenter["1.1.1.1"].Thumbprint).Should(gomega.Equal("thumbprint:1"))
+}
+
+func TestLoaderDeprecatedKeyDemotion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	l := &cloud.Loader{
+		Sources: []cloud.Source{
+			{Reader: strings.NewReader("\n[Global]\nserver = deprecated\n")},
+		},
+		UnmarshalOptions: []cloud.UnmarshalINIOptionFunc{cloud.WarnAsFatal},
+	}
+	_, err := l.Load()
+	g.Expect(err).Should(gomega.HaveOccurred())
+}
+
+func TestLoaderOverrides(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	insecure := true
+	l := &cloud.Loader{
+		Sources: []cloud.Source{
+			{Reader: strings.NewReader("\n[Global]\nuser = user\n")},
+		},
+		Overrides: &cloud.ConfigOverrides{
+			Insecure: &insecure,
+			CAFile:   "/some/ca.pem",
+			VCenterCredentials: map[string]cloud.VCenterConfig{
+				"0.0.0.0": {Username: "override-user"},
+			},
+		},
+	}
+	cfg, err := l.Load()
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	g.Expect(cfg.Global.Insecure).Should(gomega.BeTrue())
+	g.Expect(cfg.Global.CAFile).Should(gomega.Equal("/some/ca.pem"))
+	g.Expect(cfg.VCenter["0.0.0.0"].Username).Should(gomega.Equal("override-user"))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+// Config is the vSphere cloud provider's configuration.
+type Config struct {
+	// Global is the vSphere cloud provider's global configuration.
+	Global GlobalConfig `gcfg:"Global" json:"global" yaml:"global"`
+
+	// VCenter is a map of the configured vCenters, keyed by the vCenter
+	// server's IP address or FQDN.
+	VCenter map[string]VCenterConfig `gcfg:"VirtualCenter" json:"virtualCenter,omitempty" yaml:"virtualCenter,omitempty"`
+
+	// Network is the network configuration.
+	Network NetworkConfig `gcfg:"Network,omitempty" json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Disk is the disk configuration.
+	Disk DiskConfig `gcfg:"Disk,omitempty" json:"disk,omitempty" yaml:"disk,omitempty"`
+
+	// Workspace describes the default datacenter/datastore/folder used
+	// when provisioning machines.
+	Workspace WorkspaceConfig `gcfg:"Workspace" json:"workspace" yaml:"workspace"`
+
+	// Labels enables tag categories to be used to group and select
+	// machines, e.g. for topology-aware scheduling.
+	Labels LabelConfig `gcfg:"Labels,omitempty" json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// ProviderConfig carries settings that ride alongside the legacy
+	// INI-based configuration but are not themselves part of it.
+	ProviderConfig ProviderConfig `json:"providerConfig,omitempty" yaml:"providerConfig,omitempty"`
+}
+
+// GlobalConfig is the vSphere cloud provider's global configuration.
+type GlobalConfig struct {
+	// Username is the username used to access a vCenter endpoint.
+	Username string `gcfg:"user,omitempty" json:"user,omitempty" yaml:"user,omitempty"`
+
+	// Password is the password used to access a vCenter endpoint.
+	Password string `gcfg:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
+
+	// Port is the port used to access a vCenter endpoint.
+	Port string `gcfg:"port,omitempty" json:"port,omitempty" yaml:"port,omitempty"`
+
+	// Insecure is a flag that disables TLS peer verification.
+	Insecure bool `gcfg:"insecure-flag,omitempty" json:"insecure,omitempty" yaml:"insecure,omitempty"`
+
+	// Datacenters is a comma-separated list of the datacenters in which
+	// VMs are created/located.
+	Datacenters string `gcfg:"datacenters,omitempty" json:"datacenters,omitempty" yaml:"datacenters,omitempty"`
+
+	// SecretName is the name of the Secret that contains the credentials
+	// used to access a vCenter endpoint when no credentials are supplied
+	// inline.
+	SecretName string `gcfg:"secret-name,omitempty" json:"secretName,omitempty" yaml:"secretName,omitempty"`
+
+	// SecretNamespace is the namespace of the Secret referenced by
+	// SecretName.
+	SecretNamespace string `gcfg:"secret-namespace,omitempty" json:"secretNamespace,omitempty" yaml:"secretNamespace,omitempty"`
+
+	// CAFile is an optional path to a CA bundle used to verify a vCenter
+	// endpoint's certificate when Insecure is false and a per-vCenter
+	// thumbprint is not supplied.
+	CAFile string `gcfg:"ca-file,omitempty" json:"caFile,omitempty" yaml:"caFile,omitempty"`
+}
+
+// VCenterConfig is the configuration of a single vCenter endpoint.
+type VCenterConfig struct {
+	// Username is the username used to access this vCenter endpoint. If
+	// omitted, the Global username is used.
+	Username string `gcfg:"user,omitempty" json:"user,omitempty" yaml:"user,omitempty"`
+
+	// Password is the password used to access this vCenter endpoint. If
+	// omitted, the Global password is used.
+	Password string `gcfg:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
+
+	// Thumbprint is the SHA-1 thumbprint of this vCenter endpoint's
+	// certificate, used to pin the endpoint when Insecure is false.
+	Thumbprint string `gcfg:"thumbprint,omitempty" json:"thumbprint,omitempty" yaml:"thumbprint,omitempty"`
+
+	// SecretName, if set, overrides the Global secret-name for this
+	// vCenter when resolving credentials from a Secret.
+	SecretName string `gcfg:"secret-name,omitempty" json:"secretName,omitempty" yaml:"secretName,omitempty"`
+
+	// SecretNamespace, if set, overrides the Global secret-namespace for
+	// this vCenter when resolving credentials from a Secret.
+	SecretNamespace string `gcfg:"secret-namespace,omitempty" json:"secretNamespace,omitempty" yaml:"secretNamespace,omitempty"`
+}
+
+// WorkspaceConfig describes the default datacenter/datastore/folder used
+// when provisioning machines.
+type WorkspaceConfig struct {
+	// Server is the IP address or FQDN of the vCenter endpoint used for
+	// this workspace.
+	Server string `gcfg:"server,omitempty" json:"server,omitempty" yaml:"server,omitempty"`
+
+	// Datacenter is the name of the datacenter in which machines are
+	// provisioned.
+	Datacenter string `gcfg:"datacenter,omitempty" json:"datacenter,omitempty" yaml:"datacenter,omitempty"`
+
+	// Folder is the name of the VM folder in which machines are
+	// provisioned.
+	Folder string `gcfg:"folder,omitempty" json:"folder,omitempty" yaml:"folder,omitempty"`
+
+	// Datastore is the name of the default datastore used to provision
+	// machines.
+	Datastore string `gcfg:"default-datastore,omitempty" json:"datastore,omitempty" yaml:"datastore,omitempty"`
+
+	// ResourcePoolPath is the path of the resource pool in which machines
+	// are provisioned.
+	ResourcePoolPath string `gcfg:"resourcepool-path,omitempty" json:"resourcePoolPath,omitempty" yaml:"resourcePoolPath,omitempty"`
+}
+
+// NetworkConfig is the network configuration.
+type NetworkConfig struct {
+	// Name is the name of the network to which machines are attached.
+	Name string `gcfg:"public-network,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// DiskConfig is the disk configuration.
+type DiskConfig struct {
+	// SCSIControllerType is the type of SCSI controller attached to
+	// provisioned VMs' disks.
+	SCSIControllerType string `gcfg:"scsicontrollertype,omitempty" json:"scsiControllerType,omitempty" yaml:"scsiControllerType,omitempty"`
+}
+
+// LabelConfig maps tag categories to the region/zone used for
+// topology-aware scheduling.
+type LabelConfig struct {
+	// Region is the tag category used to group datacenters/clusters into
+	// regions.
+	Region string `gcfg:"region,omitempty" json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Zone is the tag category used to group clusters/hosts into zones.
+	Zone string `gcfg:"zone,omitempty" json:"zone,omitempty" yaml:"zone,omitempty"`
+}
+
+// ProviderConfig carries settings that ride alongside the legacy
+// INI-based configuration but are not themselves part of it.
+type ProviderConfig struct {
+	// Image is the default image used to provision machines when a
+	// machine does not specify one of its own.
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+}
+
+// unmarshallableConfig is a shadow of Config used to unmarshal INI data.
+// It exists because gcfg requires that "subsections" -- sections declared
+// with a key, such as [VirtualCenter "0.0.0.0"] -- be stored as a map of
+// pointers.
+//
+// +kubebuilder:object:generate=false
+type unmarshallableConfig struct {
+	Global    GlobalConfig
+	VCenter   map[string]*VCenterConfig `gcfg:"VirtualCenter"`
+	Network   NetworkConfig
+	Disk      DiskConfig
+	Workspace WorkspaceConfig
+	Labels    LabelConfig
+}
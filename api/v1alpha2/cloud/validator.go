@@ -0,0 +1,247 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+)
+
+// defaultDialTimeout is used when Validator.DialTimeout is not set.
+const defaultDialTimeout = 10 * time.Second
+
+// ValidationResult is the outcome of validating a single vCenter entry
+// from a Config.
+type ValidationResult struct {
+	// VCenter is the key of the VCenterConfig this result is for.
+	VCenter string
+
+	// Reachable is true if a TCP connection could be established to the
+	// vCenter's configured port.
+	Reachable bool
+
+	// ThumbprintVerified is true if the server's certificate matched the
+	// configured thumbprint, or the configured CA file, or no pinning was
+	// configured at all.
+	ThumbprintVerified bool
+
+	// Authenticated is true if a SOAP login succeeded with the resolved
+	// credentials.
+	Authenticated bool
+
+	// DatacenterFound, FolderFound, and DatastoreFound report whether the
+	// Workspace's objects were found via govmomi's finders.
+	DatacenterFound bool
+	FolderFound     bool
+	DatastoreFound  bool
+
+	// Err, if non-nil, is the error that stopped validation from
+	// progressing any further for this vCenter.
+	Err error
+}
+
+// Passed returns true if every check for this vCenter succeeded.
+func (r *ValidationResult) Passed() bool {
+	return r.Err == nil &&
+		r.Reachable &&
+		r.ThumbprintVerified &&
+		r.Authenticated &&
+		r.DatacenterFound &&
+		r.FolderFound &&
+		r.DatastoreFound
+}
+
+// Validator performs live validation of a Config's vCenter entries:
+// TCP reachability, TLS thumbprint pinning, SOAP authentication, and
+// existence of the configured Workspace objects.
+type Validator struct {
+	// DialTimeout bounds how long TCP and TLS dials may take. Defaults to
+	// 10 seconds.
+	DialTimeout time.Duration
+}
+
+// Validate checks every vCenter entry in c and returns a map of vCenter
+// key to ValidationResult. It does not return an error unless c itself is
+// invalid; per-vCenter failures are reported in the returned results.
+func (v *Validator) Validate(ctx context.Context, c *Config) (map[string]*ValidationResult, error) {
+	if c == nil {
+		return nil, errors.New("config is nil")
+	}
+
+	results := make(map[string]*ValidationResult, len(c.VCenter))
+	for vc, vcCfg := range c.VCenter {
+		results[vc] = v.validateOne(ctx, c, vc, vcCfg)
+	}
+	return results, nil
+}
+
+func (v *Validator) validateOne(ctx context.Context, c *Config, vc string, vcCfg VCenterConfig) *ValidationResult {
+	result := &ValidationResult{VCenter: vc}
+
+	port := c.Global.Port
+	if port == "" {
+		port = "443"
+	}
+	addr := net.JoinHostPort(vc, port)
+
+	conn, err := net.DialTimeout("tcp", addr, v.dialTimeout())
+	if err != nil {
+		result.Err = errors.Wrapf(err, "unable to reach vCenter %s", vc)
+		return result
+	}
+	conn.Close() // nolint:errcheck
+	result.Reachable = true
+
+	cert, err := fetchServerCertificate(addr, v.dialTimeout())
+	if err != nil {
+		result.Err = errors.Wrapf(err, "unable to fetch certificate for vCenter %s", vc)
+		return result
+	}
+	verified, err := verifyThumbprint(cert, vcCfg.Thumbprint, c.Global.CAFile)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "unable to verify certificate for vCenter %s", vc)
+		return result
+	}
+	result.ThumbprintVerified = verified
+	if !verified {
+		return result
+	}
+
+	username, password := vcCfg.Username, vcCfg.Password
+	if username == "" {
+		username = c.Global.Username
+	}
+	if password == "" {
+		password = c.Global.Password
+	}
+
+	u := &url.URL{
+		Scheme: "https",
+		Host:   addr,
+		Path:   "/sdk",
+		User:   url.UserPassword(username, password),
+	}
+	client, err := govmomi.NewClient(ctx, u, c.Global.Insecure)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "unable to authenticate to vCenter %s", vc)
+		return result
+	}
+	defer client.Logout(ctx) // nolint:errcheck
+	result.Authenticated = true
+
+	finder := find.NewFinder(client.Client, false)
+	if dc, err := finder.Datacenter(ctx, c.Workspace.Datacenter); err == nil && dc != nil {
+		result.DatacenterFound = true
+		finder.SetDatacenter(dc)
+	} else {
+		result.Err = errors.Wrapf(err, "datacenter %q not found on vCenter %s", c.Workspace.Datacenter, vc)
+		return result
+	}
+
+	if _, err := finder.Folder(ctx, "vm/"+c.Workspace.Folder); err == nil {
+		result.FolderFound = true
+	} else {
+		result.Err = errors.Wrapf(err, "folder %q not found on vCenter %s", c.Workspace.Folder, vc)
+		return result
+	}
+
+	if _, err := finder.Datastore(ctx, c.Workspace.Datastore); err == nil {
+		result.DatastoreFound = true
+	} else {
+		result.Err = errors.Wrapf(err, "datastore %q not found on vCenter %s", c.Workspace.Datastore, vc)
+		return result
+	}
+
+	return result
+}
+
+func (v *Validator) dialTimeout() time.Duration {
+	if v.DialTimeout > 0 {
+		return v.DialTimeout
+	}
+	return defaultDialTimeout
+}
+
+// fetchServerCertificate dials addr over TLS (without verifying the
+// server's certificate, since that is exactly what verifyThumbprint does
+// afterwards) and returns its leaf certificate.
+func fetchServerCertificate(addr string, timeout time.Duration) (*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true}) // nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close() // nolint:errcheck
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("server presented no certificates")
+	}
+	return certs[0], nil
+}
+
+// verifyThumbprint reports whether cert matches thumbprint (a SHA-1 or
+// SHA-256 hex digest, colon-separated per govmomi convention) when set,
+// or chains to caFile when thumbprint is empty, or passes trivially when
+// neither is configured.
+func verifyThumbprint(cert *x509.Certificate, thumbprint, caFile string) (bool, error) {
+	if thumbprint != "" {
+		sha1sum := strings.ToUpper(hex.EncodeToString(sha1Sum(cert.Raw))) // nolint:gosec
+		sha256sum := strings.ToUpper(hex.EncodeToString(sha256Sum(cert.Raw)))
+		want := strings.ToUpper(strings.ReplaceAll(thumbprint, ":", ""))
+		return want == sha1sum || want == sha256sum, nil
+	}
+
+	if caFile != "" {
+		caData, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to read CA file %s", caFile)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return false, fmt.Errorf("unable to parse CA file %s", caFile)
+		}
+		_, err = cert.Verify(x509.VerifyOptions{Roots: pool})
+		return err == nil, nil
+	}
+
+	return true, nil
+}
+
+func sha1Sum(data []byte) []byte { // nolint:gosec
+	sum := sha1.Sum(data) // nolint:gosec
+	return sum[:]
+}
+
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
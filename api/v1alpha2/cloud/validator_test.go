@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/soap"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/api/v1alpha2/cloud"
+)
+
+// newSimulatorConfig starts a govmomi simulator vCenter and returns a
+// Config describing it, the *simulator.Server backing it (so tests can
+// inspect its certificate), and a teardown func.
+func newSimulatorConfig(t *testing.T) (*cloud.Config, *simulator.Server, func()) {
+	t.Helper()
+
+	model := simulator.VPX()
+	model.Datacenter = 1
+	model.Folder = 1
+	model.Datastore = 1
+	if err := model.Create(); err != nil {
+		t.Fatalf("unable to create simulator model: %v", err)
+	}
+
+	// The Validator always performs a TLS handshake before attempting a
+	// SOAP login, so the simulator must actually serve TLS rather than
+	// plain HTTP.
+	model.Service.TLS = new(tls.Config)
+
+	// simulator.SessionManager.Authenticate special-cases the simulator's
+	// DefaultLogin to accept any non-empty username/password, so a
+	// non-default credential is required here for auth-failure tests to
+	// actually exercise a rejected login.
+	model.Service.Listen = &url.URL{User: url.UserPassword("testuser", "testpass")}
+
+	server := model.Service.NewServer()
+
+	u, err := url.Parse(server.URL.String())
+	if err != nil {
+		t.Fatalf("unable to parse simulator URL: %v", err)
+	}
+	password, _ := u.User.Password()
+
+	config := &cloud.Config{
+		Global: cloud.GlobalConfig{
+			Username: u.User.Username(),
+			Password: password,
+			Port:     u.Port(),
+			Insecure: true,
+		},
+		VCenter: map[string]cloud.VCenterConfig{
+			u.Hostname(): {},
+		},
+		Workspace: cloud.WorkspaceConfig{
+			Datacenter: "DC0",
+			Folder:     "F0",
+			Datastore:  "LocalDS_0",
+		},
+	}
+
+	return config, server, func() {
+		server.Close()
+		model.Remove()
+	}
+}
+
+func TestValidatorValidate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	config, _, teardown := newSimulatorConfig(t)
+	defer teardown()
+
+	v := &cloud.Validator{}
+	results, err := v.Validate(context.Background(), config)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+	g.Expect(results).Should(gomega.HaveLen(1))
+
+	for vc, result := range results {
+		g.Expect(result.Reachable).Should(gomega.BeTrue(), vc)
+		g.Expect(result.Authenticated).Should(gomega.BeTrue(), vc)
+		g.Expect(result.Passed()).Should(gomega.BeTrue(), vc)
+	}
+}
+
+func TestValidatorValidateAuthFailure(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	config, _, teardown := newSimulatorConfig(t)
+	defer teardown()
+
+	for vc, vcCfg := range config.VCenter {
+		config.Global.Password = "wrong-password"
+		config.VCenter[vc] = vcCfg
+	}
+
+	v := &cloud.Validator{}
+	results, err := v.Validate(context.Background(), config)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	for vc, result := range results {
+		g.Expect(result.Authenticated).Should(gomega.BeFalse(), vc)
+		g.Expect(result.Passed()).Should(gomega.BeFalse(), vc)
+	}
+}
+
+func TestValidatorValidateMissingWorkspace(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	config, _, teardown := newSimulatorConfig(t)
+	defer teardown()
+
+	config.Workspace.Datacenter = "does-not-exist"
+
+	v := &cloud.Validator{}
+	results, err := v.Validate(context.Background(), config)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	for vc, result := range results {
+		g.Expect(result.DatacenterFound).Should(gomega.BeFalse(), vc)
+		g.Expect(result.Passed()).Should(gomega.BeFalse(), vc)
+	}
+}
+
+func TestValidatorValidateThumbprintMismatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	config, _, teardown := newSimulatorConfig(t)
+	defer teardown()
+
+	for vc, vcCfg := range config.VCenter {
+		vcCfg.Thumbprint = "00:11:22:33:44:55:66:77:88:99:AA:BB:CC:DD:EE:FF:00:11:22:33"
+		config.VCenter[vc] = vcCfg
+	}
+
+	v := &cloud.Validator{}
+	results, err := v.Validate(context.Background(), config)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	for vc, result := range results {
+		g.Expect(result.ThumbprintVerified).Should(gomega.BeFalse(), vc)
+		g.Expect(result.Passed()).Should(gomega.BeFalse(), vc)
+	}
+}
+
+func TestValidatorValidateThumbprintMatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	config, server, teardown := newSimulatorConfig(t)
+	defer teardown()
+
+	thumbprint := soap.ThumbprintSHA1(server.Certificate())
+	for vc, vcCfg := range config.VCenter {
+		vcCfg.Thumbprint = thumbprint
+		config.VCenter[vc] = vcCfg
+	}
+
+	v := &cloud.Validator{}
+	results, err := v.Validate(context.Background(), config)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	for vc, result := range results {
+		g.Expect(result.ThumbprintVerified).Should(gomega.BeTrue(), vc)
+		g.Expect(result.Passed()).Should(gomega.BeTrue(), vc)
+	}
+}
+
+func TestValidatorValidateCAFile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	config, server, teardown := newSimulatorConfig(t)
+	defer teardown()
+
+	caFile, err := server.CertificateFile()
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+	config.Global.CAFile = caFile
+
+	v := &cloud.Validator{}
+	results, err := v.Validate(context.Background(), config)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	for vc, result := range results {
+		g.Expect(result.ThumbprintVerified).Should(gomega.BeTrue(), vc)
+		g.Expect(result.Passed()).Should(gomega.BeTrue(), vc)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// MarshalYAML marshals the cloud provider configuration to YAML-style
+// configuration data using the same logical schema as MarshalINI.
+func (c *Config) MarshalYAML() ([]byte, error) {
+	if c == nil {
+		return nil, errors.New("config is nil")
+	}
+	return yaml.Marshal(c)
+}
+
+// UnmarshalYAML unmarshals the cloud provider configuration from YAML-style
+// configuration data using the same logical schema as UnmarshalINI.
+func (c *Config) UnmarshalYAML(data []byte, optFuncs ...UnmarshalINIOptionFunc) error {
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+
+	opts := &UnmarshalINIOptions{}
+	for _, setOpts := range optFuncs {
+		setOpts(opts)
+	}
+	if opts.ExpandEnv {
+		return expandConfig(c, opts)
+	}
+	return nil
+}
+
+// MarshalJSON marshals the cloud provider configuration to JSON-style
+// configuration data using the same logical schema as MarshalINI.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	if c == nil {
+		return nil, errors.New("config is nil")
+	}
+	type configAlias Config
+	return json.Marshal((*configAlias)(c))
+}
+
+// UnmarshalJSON unmarshals the cloud provider configuration from
+// JSON-style configuration data using the same logical schema as
+// UnmarshalINI.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type configAlias Config
+	return json.Unmarshal(data, (*configAlias)(c))
+}
+
+// MarshalJSON marshals the global configuration to JSON-style
+// configuration data.
+func (g *GlobalConfig) MarshalJSON() ([]byte, error) {
+	type globalConfigAlias GlobalConfig
+	return json.Marshal((*globalConfigAlias)(g))
+}
+
+// UnmarshalJSON unmarshals the global configuration from JSON-style
+// configuration data.
+func (g *GlobalConfig) UnmarshalJSON(data []byte) error {
+	type globalConfigAlias GlobalConfig
+	return json.Unmarshal(data, (*globalConfigAlias)(g))
+}
+
+// MarshalJSON marshals a vCenter's configuration to JSON-style
+// configuration data.
+func (v *VCenterConfig) MarshalJSON() ([]byte, error) {
+	type vCenterConfigAlias VCenterConfig
+	return json.Marshal((*vCenterConfigAlias)(v))
+}
+
+// UnmarshalJSON unmarshals a vCenter's configuration from JSON-style
+// configuration data.
+func (v *VCenterConfig) UnmarshalJSON(data []byte) error {
+	type vCenterConfigAlias VCenterConfig
+	return json.Unmarshal(data, (*vCenterConfigAlias)(v))
+}
+
+// MarshalJSON marshals the workspace configuration to JSON-style
+// configuration data.
+func (w *WorkspaceConfig) MarshalJSON() ([]byte, error) {
+	type workspaceConfigAlias WorkspaceConfig
+	return json.Marshal((*workspaceConfigAlias)(w))
+}
+
+// UnmarshalJSON unmarshals the workspace configuration from JSON-style
+// configuration data.
+func (w *WorkspaceConfig) UnmarshalJSON(data []byte) error {
+	type workspaceConfigAlias WorkspaceConfig
+	return json.Unmarshal(data, (*workspaceConfigAlias)(w))
+}
+
+// LoadConfig sniffs whether data is INI, JSON, or YAML and unmarshals it
+// into a new Config accordingly. JSON is a subset of YAML, so JSON data is
+// also accepted by the YAML path; it is detected separately only so the
+// common case doesn't pay for a failed YAML parse attempt.
+func LoadConfig(data []byte, optFuncs ...UnmarshalINIOptionFunc) (*Config, error) {
+	c := &Config{}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return c, nil
+	case trimmed[0] == '{':
+		if err := c.UnmarshalJSON(trimmed); err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal JSON config")
+		}
+		return c, applyExpandEnvOpts(c, optFuncs)
+	case isINI(trimmed):
+		if err := c.UnmarshalINI(trimmed, optFuncs...); err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal INI config")
+		}
+		return c, nil
+	default:
+		if err := c.UnmarshalYAML(trimmed, optFuncs...); err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal YAML config")
+		}
+		return c, nil
+	}
+}
+
+// isINI returns true if the first non-empty line of data looks like an INI
+// section header, e.g. "[Global]" or `[VirtualCenter "0.0.0.0"]`.
+func isINI(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		return strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]")
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/api/v1alpha2/cloud"
+)
+
+// roundTripTestCases mirrors the scenarios exercised by TestMarshalINI and
+// TestUnmarshalINI so the YAML and JSON codecs cover the same ground as the
+// INI codec.
+var roundTripTestCases = []struct {
+	testName  string
+	configObj cloud.Config
+}{
+	{
+		testName: "Username and password in global section",
+		configObj: cloud.Config{
+			Global: cloud.GlobalConfig{
+				Username:    "user",
+				Password:    "password",
+				Datacenters: "us-west",
+			},
+			VCenter: map[string]cloud.VCenterConfig{
+				"0.0.0.0": {},
+			},
+			Workspace: cloud.WorkspaceConfig{
+				Server:     "0.0.0.0",
+				Datacenter: "us-west",
+				Folder:     "kubernetes",
+				Datastore:  "default",
+			},
+		},
+	},
+	{
+		testName: "Username and password in vCenter section",
+		configObj: cloud.Config{
+			Global: cloud.GlobalConfig{
+				Port:        "443",
+				Insecure:    true,
+				Datacenters: "us-west",
+			},
+			VCenter: map[string]cloud.VCenterConfig{
+				"0.0.0.0": {
+					Username: "user",
+					Password: "password",
+				},
+			},
+			Workspace: cloud.WorkspaceConfig{
+				Server:     "0.0.0.0",
+				Datacenter: "us-west",
+				Folder:     "kubernetes",
+			},
+		},
+	},
+	{
+		testName: "SecretName and SecretNamespace",
+		configObj: cloud.Config{
+			Global: cloud.GlobalConfig{
+				SecretName:      "vccreds",
+				SecretNamespace: "kube-system",
+				Datacenters:     "us-west",
+			},
+			VCenter: map[string]cloud.VCenterConfig{
+				"0.0.0.0": {},
+			},
+			Workspace: cloud.WorkspaceConfig{
+				Server:     "0.0.0.0",
+				Datacenter: "us-west",
+				Folder:     "kubernetes",
+			},
+		},
+	},
+	{
+		testName: "Multiple virtual centers with different thumbprints",
+		configObj: cloud.Config{
+			Global: cloud.GlobalConfig{
+				Username:    "user",
+				Password:    "password",
+				Datacenters: "us-west",
+			},
+			VCenter: map[string]cloud.VCenterConfig{
+				"0.0.0.0": {
+					Thumbprint: "thumbprint:0",
+				},
+				"no_thumbprint": {},
+				"1.1.1.1": {
+					Thumbprint: "thumbprint:1",
+				},
+			},
+			Workspace: cloud.WorkspaceConfig{
+				Server:     "0.0.0.0",
+				Datacenter: "us-west",
+				Folder:     "kubernetes",
+			},
+		},
+	},
+	{
+		testName: "Multiple vCenters using global CA cert",
+		configObj: cloud.Config{
+			Global: cloud.GlobalConfig{
+				Datacenters:     "us-west",
+				SecretName:      "vccreds",
+				SecretNamespace: "kube-system",
+				CAFile:          "/some/path/to/my/trusted/ca.pem",
+			},
+			VCenter: map[string]cloud.VCenterConfig{
+				"0.0.0.0": {},
+				"1.1.1.1": {},
+			},
+			Workspace: cloud.WorkspaceConfig{
+				Server:     "0.0.0.0",
+				Datacenter: "us-west",
+				Folder:     "kubernetes",
+			},
+		},
+	},
+}
+
+func TestMarshalUnmarshalYAML(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	for _, tc := range roundTripTestCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			buf, err := tc.configObj.MarshalYAML()
+			g.Expect(err).ShouldNot(gomega.HaveOccurred(), "unexpected error when marshalling data")
+
+			var actualConfig cloud.Config
+			g.Expect(actualConfig.UnmarshalYAML(buf)).To(gomega.Succeed(), "unexpected error when unmarshalling data")
+
+			g.Expect(actualConfig).Should(
+				gomega.Equal(tc.configObj),
+				"actual config does not match expected config")
+		})
+	}
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	for _, tc := range roundTripTestCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			buf, err := tc.configObj.MarshalJSON()
+			g.Expect(err).ShouldNot(gomega.HaveOccurred(), "unexpected error when marshalling data")
+
+			var actualConfig cloud.Config
+			g.Expect(actualConfig.UnmarshalJSON(buf)).To(gomega.Succeed(), "unexpected error when unmarshalling data")
+
+			g.Expect(actualConfig).Should(
+				gomega.Equal(tc.configObj),
+				"actual config does not match expected config")
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	for _, tc := range roundTripTestCases {
+		t.Run(tc.testName+" (INI)", func(t *testing.T) {
+			ini, err := tc.configObj.MarshalINI()
+			g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+			actualConfig, err := cloud.LoadConfig(ini)
+			g.Expect(err).ShouldNot(gomega.HaveOccurred())
+			g.Expect(*actualConfig).Should(gomega.Equal(tc.configObj))
+		})
+
+		t.Run(tc.testName+" (YAML)", func(t *testing.T) {
+			data, err := tc.configObj.MarshalYAML()
+			g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+			actualConfig, err := cloud.LoadConfig(data)
+			g.Expect(err).ShouldNot(gomega.HaveOccurred())
+			g.Expect(*actualConfig).Should(gomega.Equal(tc.configObj))
+		})
+
+		t.Run(tc.testName+" (JSON)", func(t *testing.T) {
+			data, err := tc.configObj.MarshalJSON()
+			g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+			actualConfig, err := cloud.LoadConfig(data)
+			g.Expect(err).ShouldNot(gomega.HaveOccurred())
+			g.Expect(*actualConfig).Should(gomega.Equal(tc.configObj))
+		})
+	}
+}
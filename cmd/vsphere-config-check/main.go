@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command vsphere-config-check dry-runs a cloud provider Config against
+// the vCenter(s) it describes, without applying it to a cluster.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/api/v1alpha2/cloud"
+)
+
+func main() {
+	var (
+		configPath string
+		validate   bool
+	)
+	flag.StringVar(&configPath, "config", "", "path to the cloud provider Config file (INI, YAML, or JSON)")
+	flag.BoolVar(&validate, "validate", false, "perform live validation of every vCenter in the Config")
+	flag.Parse()
+
+	if configPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: vsphere-config-check --config <path> [--validate]")
+		os.Exit(2)
+	}
+
+	if err := run(configPath, validate); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, validate bool) error {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", configPath, err)
+	}
+
+	config, err := cloud.LoadConfig(data)
+	if err != nil {
+		return fmt.Errorf("unable to load %s: %w", configPath, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("config is invalid: %w", err)
+	}
+	fmt.Println("config is structurally valid")
+
+	if !validate {
+		return nil
+	}
+
+	validator := &cloud.Validator{}
+	results, err := validator.Validate(context.Background(), config)
+	if err != nil {
+		return fmt.Errorf("unable to validate config: %w", err)
+	}
+
+	failed := false
+	for vc, result := range results {
+		status := "OK"
+		if !result.Passed() {
+			status = "FAILED"
+			failed = true
+		}
+		fmt.Printf("vCenter %s: %s\n", vc, status)
+		if result.Err != nil {
+			fmt.Printf("  %v\n", result.Err)
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("one or more vCenters failed validation")
+	}
+	return nil
+}